@@ -1,6 +1,9 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,6 +18,8 @@ import (
 type testFlags struct {
 	from          string // default: 'index.scip'
 	commentSyntax string // default: '//'
+	fix           bool
+	format        string // default: 'pretty'
 }
 
 func testCommand() cli.Command {
@@ -29,9 +34,24 @@ on the expected file format of the test files.`,
 		Flags: []cli.Flag{
 			fromFlag(&testFlags.from),
 			commentSyntaxFlag(&testFlags.commentSyntax),
+			&cli.BoolFlag{
+				Name:        "fix",
+				Aliases:     []string{"update"},
+				Usage:       "rewrite the assertion comments under the test directory to match the SCIP index instead of failing on mismatches",
+				Destination: &testFlags.fix,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Usage:       "output format for test results: pretty, json, junit, sarif",
+				Value:       "pretty",
+				Destination: &testFlags.format,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			dir := c.Args().Get(0)
+			if testFlags.fix {
+				return fixMain(dir, testFlags)
+			}
 			return testMain(dir, testFlags)
 		},
 	}
@@ -44,7 +64,10 @@ func testMain(directory string, flags testFlags) error {
 		return err
 	}
 
-	hasFailure := false
+	reporter, err := newTestReporter(flags.format)
+	if err != nil {
+		return err
+	}
 
 	for _, document := range index.Documents {
 		sourceFilePath := filepath.Join(directory, document.RelativePath)
@@ -54,12 +77,21 @@ func testMain(directory string, flags testFlags) error {
 			return err
 		}
 
-		failures := []string{}
+		problems := []testProblem{}
 		successCount := 0
 
+		commentSyntax := commentSyntaxForFile(document.RelativePath, flags.commentSyntax)
 		lines := strings.Split(string(data), "\n")
+		markers := collectMarkers(lines, commentSyntax)
+		rangeTestCases := collectRangeTestCases(lines, commentSyntax)
+		manifestTestCases, err := collectManifestTestCases(sourceFilePath, markers)
+		if err != nil {
+			return err
+		}
 		for lineNumber := 0; lineNumber < len(lines); lineNumber++ {
-			testCasesAtLine, usedLines := testCasesForLine(lineNumber, lines, flags.commentSyntax)
+			testCasesAtLine, usedLines := testCasesForLine(lineNumber, lines, commentSyntax, markers)
+			testCasesAtLine = append(testCasesAtLine, rangeTestCases[lineNumber]...)
+			testCasesAtLine = append(testCasesAtLine, manifestTestCases[lineNumber]...)
 
 			// if the test file contains no test lines, skip it. Only test the lines
 			// that the test file dictates should be tested
@@ -67,10 +99,11 @@ func testMain(directory string, flags testFlags) error {
 				continue
 			}
 
-			attributes := attributesForOccurrencesAtLine(lineNumber, document.Occurrences)
 			for _, testCase := range testCasesAtLine {
-				if !isValidTestCase(testCase, attributes) {
-					failures = append(failures, formatFailure(lineNumber, testCase, attributes))
+				attr := testCase.attribute
+				attributes := attributesForRange(attr.startLine, attr.start, attr.endLine, attr.endCol, document)
+				if ok, category := isValidTestCase(testCase, attributes); !ok {
+					problems = append(problems, newTestProblem(document.RelativePath, lineNumber, lines, testCase, attributes, category))
 				} else {
 					successCount++
 				}
@@ -79,36 +112,247 @@ func testMain(directory string, flags testFlags) error {
 			lineNumber += usedLines
 		}
 
-		if len(failures) > 0 {
-			hasFailure = true
-			red := color.New(color.FgRed)
-			red.Printf("✗ %s\n", document.RelativePath)
+		sortTestProblems(problems)
+		reporter.reportDocument(document.RelativePath, problems, successCount)
+	}
+
+	return reporter.finish()
+}
 
-			for _, failure := range failures {
-				fmt.Println(indent(failure, 4))
+// fixMain rewrites the assertion comments under directory so that they match
+// what the SCIP index actually reports, the same way `go test -update` refreshes
+// golden files instead of merely reporting that they're stale.
+func fixMain(directory string, flags testFlags) error {
+	index, err := readFromOption(flags.from)
+	if err != nil {
+		return err
+	}
+
+	for _, document := range index.Documents {
+		sourceFilePath := filepath.Join(directory, document.RelativePath)
+
+		data, err := os.ReadFile(sourceFilePath)
+		if err != nil {
+			return err
+		}
+
+		commentSyntax := commentSyntaxForFile(document.RelativePath, flags.commentSyntax)
+		lines := strings.Split(string(data), "\n")
+		markers := collectMarkers(lines, commentSyntax)
+		manifestTestCases, err := collectManifestTestCases(sourceFilePath, markers)
+		if err != nil {
+			return err
+		}
+		newLines := []string{}
+
+		for lineNumber := 0; lineNumber < len(lines); lineNumber++ {
+			newLines = append(newLines, lines[lineNumber])
+
+			existingTestCases, usedLines := testCasesForLine(lineNumber, lines, commentSyntax, markers)
+			blockLines := lines[lineNumber+1 : lineNumber+1+usedLines]
+
+			attributes := attributesForRange(lineNumber, 0, lineNumber, 0, document)
+			attributes = withoutCoveredAttributes(attributes, manifestTestCases[lineNumber])
+			attributes = withoutCoveredAttributes(attributes, collectInlineMarkerTestCases(blockLines, commentSyntax, markers))
+
+			preserved := preserveUnregeneratedLines(blockLines, commentSyntax)
+			if len(attributes) == 0 && len(preserved) == 0 {
+				lineNumber += usedLines
+				continue
 			}
-		} else {
-			green := color.New(color.FgGreen)
-			green.Printf("✓ %s (%d assertions)\n", document.RelativePath, successCount)
+
+			newLines = append(newLines, formatTestCaseBlock(attributes, existingTestCases, commentSyntax)...)
+			newLines = append(newLines, preserved...)
+			lineNumber += usedLines
 		}
-	}
 
-	if hasFailure {
-		return cli.Exit("", 1)
+		if err := os.WriteFile(sourceFilePath, []byte(strings.Join(newLines, "\n")), 0o644); err != nil {
+			return err
+		}
+
+		green := color.New(color.FgGreen)
+		green.Printf("✓ %s (assertions updated)\n", document.RelativePath)
 	}
 
 	return nil
 }
 
+// formatTestCaseBlock renders attributes back into the comment lines that
+// testCasesForLine/parseTestCase would accept. When an attribute corresponds to
+// an existing test case, that test case's enforceLength preference is preserved.
+func formatTestCaseBlock(attributes []*symbolAttribute, existingTestCases []*symbolAttributeTestCase, commentSyntax string) []string {
+	lines := []string{}
+	for _, attr := range attributes {
+		lines = append(lines, formatTestCaseLine(attr, enforceLengthFor(attr, existingTestCases), commentSyntax))
+		for _, add := range attr.additionalData {
+			lines = append(lines, fmt.Sprintf("%s> %s", commentSyntax, add))
+		}
+	}
+	return lines
+}
+
+// preserveUnregeneratedLines extracts the lines from a comment block that --fix has
+// no way to regenerate from a single attribute, carrying them over verbatim instead
+// of dropping them when the rest of the block is rewritten. This covers two
+// grammars, checked in source order so interleaved uses of both keep their original
+// relative position:
+//
+//   - `^^^^ start` / `^^^ end ...` range selectors, along with any `>`-prefixed
+//     continuation lines that follow them. --fix doesn't understand the multi-line
+//     range selector grammar well enough to regenerate it.
+//   - `//@mark(...)` / `//@kind(marker, ...)` marker directives. A marker declaration
+//     or marker-based assertion names a marker rather than being derived from a
+//     single attribute, so --fix can't regenerate it either.
+func preserveUnregeneratedLines(blockLines []string, commentSyntax string) []string {
+	preserved := []string{}
+	for i := 0; i < len(blockLines); i++ {
+		line := blockLines[i]
+
+		if isRangeSelectorFragment(line, commentSyntax) {
+			preserved = append(preserved, line)
+
+			continuationCount := len(parseAdditionalData(blockLines[i+1:], commentSyntax))
+			preserved = append(preserved, blockLines[i+1:i+1+continuationCount]...)
+			i += continuationCount
+			continue
+		}
+
+		if _, _, ok := parseMarkerDirective(line, commentSyntax); ok {
+			preserved = append(preserved, line)
+		}
+	}
+	return preserved
+}
+
+// collectInlineMarkerTestCases parses the `//@kind(marker, ...)` assertion
+// directives (skipping `@mark` itself, which declares a position rather than
+// asserting on it) out of a comment block into symbolAttributeTestCases, the same
+// way collectManifestTestCases does for manifest entries. fixMain uses this to know
+// which attributes are already covered by an inline marker-based assertion, since
+// those lines are preserved verbatim rather than regenerated from attributes.
+func collectInlineMarkerTestCases(blockLines []string, commentSyntax string, markers map[string]*marker) []*symbolAttributeTestCase {
+	testCases := []*symbolAttributeTestCase{}
+	for _, line := range blockLines {
+		if testCase, _ := markerDirectiveTestCase(line, commentSyntax, markers); testCase != nil {
+			testCases = append(testCases, testCase)
+		}
+	}
+	return testCases
+}
+
+// markerDirectiveTestCase resolves a single comment line as a marker directive, if it
+// is one. isDirective reports whether line was a `//@kind(...)` directive at all,
+// independent of whether it produced a test case - the caller needs this to still
+// count a `@mark` declaration (which never asserts anything) or an unresolvable
+// reference as "consumed", rather than falling through to parseTestCase. The
+// returned test case is nil for a `@mark` declaration and for a directive whose
+// marker name markerTestCase couldn't resolve.
+func markerDirectiveTestCase(line string, commentSyntax string, markers map[string]*marker) (testCase *symbolAttributeTestCase, isDirective bool) {
+	directiveKind, args, ok := parseMarkerDirective(line, commentSyntax)
+	if !ok {
+		return nil, false
+	}
+	// `@mark` declarations were already resolved by collectMarkers; they don't
+	// themselves assert anything.
+	if directiveKind == "mark" {
+		return nil, true
+	}
+	testCase, _ = markerTestCase(directiveKind, args, markers)
+	return testCase, true
+}
+
+// withoutCoveredAttributes drops any attribute already asserted on by one of
+// coveringTestCases, so --fix doesn't stamp a duplicate inline `^`-style comment
+// over an assertion that's tracked elsewhere - in a .expect.json manifest entry, or
+// in an inline `//@kind(marker, ...)` directive referencing a marker declared by
+// `//@mark`. Matching on kind, start, and data (rather than just kind+start) keeps
+// sibling attributes that legitimately share a column, such as two
+// `implementation` relationships on the same occurrence, from being dropped when
+// only one of them is covered. data is compared with the same wildcard-aware rule
+// scip test itself uses, so a `.` placeholder segment still counts as covering
+// the attribute.
+func withoutCoveredAttributes(attributes []*symbolAttribute, coveringTestCases []*symbolAttributeTestCase) []*symbolAttribute {
+	if len(coveringTestCases) == 0 {
+		return attributes
+	}
+
+	result := make([]*symbolAttribute, 0, len(attributes))
+	for _, attr := range attributes {
+		covered := false
+		for _, testCase := range coveringTestCases {
+			if testCase.attribute.kind == attr.kind && testCase.attribute.start == attr.start && symbolDataMatches(testCase.attribute.data, attr.data) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, attr)
+		}
+	}
+	return result
+}
+
+// enforceLengthFor looks up whether a previous test case already asserted on
+// this attribute with an explicit length (`^^^`), so --fix doesn't silently
+// downgrade a length assertion to a bare `^`.
+func enforceLengthFor(attr *symbolAttribute, existingTestCases []*symbolAttributeTestCase) bool {
+	for _, testCase := range existingTestCases {
+		if testCase.attribute.kind == attr.kind && testCase.attribute.start == attr.start {
+			return testCase.enforceLength
+		}
+	}
+	return false
+}
+
+// formatTestCaseLine renders a single assertion line for attr, aligning the
+// selector under the source column the attribute starts at. A `^` selector is
+// used where possible, with a single `^` unless enforceLength is set, in which
+// case the carets span the token's length. A `^` always sits at or after
+// column len(commentSyntax), since the comment prefix occupies the columns
+// before it - so for a column earlier than that (e.g. a symbol at the very
+// start of a go.mod/BUILD line), the `<-` form is used instead, indenting the
+// comment prefix itself by attr.start to reproduce the column.
+func formatTestCaseLine(attr *symbolAttribute, enforceLength bool, commentSyntax string) string {
+	if attr.start < len(commentSyntax) {
+		indent := strings.Repeat(" ", attr.start)
+		return fmt.Sprintf("%s%s <- %s %s", indent, commentSyntax, attr.kind, attr.data)
+	}
+
+	caretCount := 1
+	if enforceLength {
+		caretCount = attr.length
+	}
+	if caretCount < 1 {
+		// A length derived from a malformed or cross-line attribute (e.g. an
+		// enclosing_range whose endpoints land on different lines) must not produce
+		// a negative repeat count, which would panic strings.Repeat.
+		caretCount = 1
+	}
+
+	padding := attr.start - len(commentSyntax)
+	caretLine := commentSyntax + strings.Repeat(" ", padding) + strings.Repeat("^", caretCount)
+	return fmt.Sprintf("%s %s %s", caretLine, attr.kind, attr.data)
+}
+
 // symbolAttribute refers to a single attribute of a symbol.
 // This can be a definition, reference, documentation, or diagnostic
 type symbolAttribute struct {
+	// the line number this attribute starts on
+	startLine int
+
 	// the column number where this symbol starts
 	start int
 
-	// the length of the symbol's name
+	// the length of the symbol's name, meaningful when startLine == endLine
 	length int
 
+	// the line number this attribute ends on. Equal to startLine for every
+	// attribute except a multi-line `^^^^ start` / `^^^ end` range selector
+	endLine int
+
+	// the column this attribute ends on, only meaningful when endLine != startLine
+	endCol int
+
 	// the type of attribute that this is
 	kind string
 
@@ -134,7 +378,7 @@ type symbolAttributeTestCase struct {
 //
 // Returns the list of symbolAttributeTestCase(s) for the provided line, and the number of
 // of lines that were "consumed" by the cases on this line
-func testCasesForLine(lineNumber int, lines []string, commentSyntax string) ([]*symbolAttributeTestCase, int) {
+func testCasesForLine(lineNumber int, lines []string, commentSyntax string, markers map[string]*marker) ([]*symbolAttributeTestCase, int) {
 	testCases := []*symbolAttributeTestCase{}
 
 	// if the specified lineNumber is outside the bounds of lines
@@ -152,7 +396,28 @@ func testCasesForLine(lineNumber int, lines []string, commentSyntax string) ([]*
 			// if the line does not start with a comment, we're done. break
 			break
 		}
-		testCase := parseTestCase(line, lines[i+1:], commentSyntax)
+
+		if testCase, isDirective := markerDirectiveTestCase(line, commentSyntax, markers); isDirective {
+			usedLines++
+			if testCase != nil {
+				testLines = append(testLines, testCase)
+			}
+			continue
+		}
+
+		if isRangeSelectorFragment(line, commentSyntax) {
+			// `^^^^ start` / `^^^ end ...` lines were already resolved into a single,
+			// merged test case by collectRangeTestCases; parsing them again here would
+			// produce a phantom test case for kind "start"/"end" that can never match.
+			// Any `>`-prefixed continuation lines belong to that same merged test case
+			// and must be skipped too, rather than falling through to parseTestCase.
+			additionalData := parseAdditionalData(lines[i+1:], commentSyntax)
+			i += len(additionalData)
+			usedLines += 1 + len(additionalData)
+			continue
+		}
+
+		testCase := parseTestCase(lineNumber, line, lines[i+1:], commentSyntax)
 
 		testLines = append(testLines, testCase)
 		i += len(testCase.attribute.additionalData)
@@ -162,58 +427,475 @@ func testCasesForLine(lineNumber int, lines []string, commentSyntax string) ([]*
 	return testLines, usedLines
 }
 
-func attributesForOccurrencesAtLine(lineNumber int, occurrences []*scip.Occurrence) []*symbolAttribute {
-	result := []*symbolAttribute{}
-	for _, occ := range occurrences {
-		if occ.Range[0] == int32(lineNumber) {
-			pos, _ := scip.NewRange(occ.Range)
-
-			start := int(pos.Start.Character)
-			length := int(pos.End.Character - pos.Start.Character)
-
-			kind := "reference"
-			if scip.SymbolRole_Definition.Matches(occ) {
-				kind = "definition"
-			} else if scip.SymbolRole_ForwardDefinition.Matches(occ) {
-				kind = "forward_definition"
+// commentSyntaxByFileName maps well-known build-system file names and extensions to
+// their comment syntax, so marker assertions can live inside files like go.mod or
+// BUILD without the caller having to pass --comment-syntax by hand.
+var commentSyntaxByFileName = map[string]string{
+	"go.mod":      "#",
+	"go.sum":      "#",
+	"BUILD":       "#",
+	"BUILD.bazel": "#",
+	".bzl":        "#",
+}
+
+// commentSyntaxForFile returns the comment syntax to use for relativePath, preferring
+// a known build-system convention over the caller-supplied fallback.
+func commentSyntaxForFile(relativePath string, fallback string) string {
+	base := filepath.Base(relativePath)
+	if syntax, ok := commentSyntaxByFileName[base]; ok {
+		return syntax
+	}
+	if syntax, ok := commentSyntaxByFileName[filepath.Ext(relativePath)]; ok {
+		return syntax
+	}
+	return fallback
+}
+
+// marker is a named position, declared via `@mark(name, "pattern")`, that later
+// assertions can refer to instead of repeating a `^`/`<-` selector.
+type marker struct {
+	line   int
+	start  int
+	length int
+}
+
+// collectMarkers scans every line of a file for `@mark(name, "pattern")` directives,
+// resolving each to the position of pattern's first occurrence on the line
+// immediately before the directive. This mirrors the `//@mark` convention from golang.org/x/tools/go/expect.
+func collectMarkers(lines []string, commentSyntax string) map[string]*marker {
+	markers := map[string]*marker{}
+
+	for lineNumber, line := range lines {
+		directiveKind, args, ok := parseMarkerDirective(line, commentSyntax)
+		if !ok || directiveKind != "mark" || len(args) < 2 || lineNumber == 0 {
+			continue
+		}
+
+		name, pattern := args[0], args[1]
+		codeLine := lines[lineNumber-1]
+		start := strings.Index(codeLine, pattern)
+		if start == -1 {
+			continue
+		}
+
+		markers[name] = &marker{
+			line:   lineNumber - 1,
+			start:  start,
+			length: len(pattern),
+		}
+	}
+
+	return markers
+}
+
+// parseMarkerDirective recognizes a `//@kind(arg, "arg", ...)` comment line and
+// returns the directive kind (e.g. "mark", "definition", "diagnostic") along with
+// its parsed arguments. Quoted arguments may contain commas and spaces.
+func parseMarkerDirective(line string, commentSyntax string) (kind string, args []string, ok bool) {
+	prefix := commentSyntax + "@"
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, prefix) {
+		return "", nil, false
+	}
+
+	directive := strings.TrimPrefix(trimmed, prefix)
+	open := strings.Index(directive, "(")
+	if open == -1 || !strings.HasSuffix(directive, ")") {
+		return "", nil, false
+	}
+
+	kind = directive[:open]
+	rawArgs := directive[open+1 : len(directive)-1]
+
+	reader := csv.NewReader(strings.NewReader(rawArgs))
+	reader.TrimLeadingSpace = true
+	parsedArgs, err := reader.Read()
+	if err != nil {
+		return "", nil, false
+	}
+
+	return kind, parsedArgs, true
+}
+
+// markerTestCase builds a symbolAttributeTestCase for an assertion directive
+// (everything but `@mark`) whose first argument names a marker declared elsewhere
+// in the file. The marker's range is always matched exactly, the same as an
+// explicit `^^^` selector. Shared by inline `//@kind(...)` directives and
+// `.expect.json` manifest entries, which resolve to the same args shape.
+func markerTestCase(kind string, args []string, markers map[string]*marker) (*symbolAttributeTestCase, bool) {
+	if len(args) < 1 {
+		return nil, false
+	}
+
+	m, ok := markers[args[0]]
+	if !ok {
+		return nil, false
+	}
+
+	data := ""
+	additionalData := []string{}
+	if len(args) > 1 {
+		data = args[1]
+	}
+	if len(args) > 2 {
+		additionalData = args[2:]
+	}
+
+	return &symbolAttributeTestCase{
+		attribute: &symbolAttribute{
+			startLine:      m.line,
+			start:          m.start,
+			length:         m.length,
+			endLine:        m.line,
+			kind:           kind,
+			data:           data,
+			additionalData: additionalData,
+		},
+		enforceLength: true,
+	}, true
+}
+
+// manifestEntry is a single assertion in a sibling `.expect.json` manifest. It
+// mirrors an inline `//@kind(marker, ...)` directive, except the marker it
+// references was declared with `//@mark` in the source file the manifest
+// accompanies, rather than in the manifest itself.
+type manifestEntry struct {
+	Kind   string   `json:"kind"`
+	Marker string   `json:"marker"`
+	Args   []string `json:"args,omitempty"`
+}
+
+// collectManifestTestCases reads sourceFilePath's sibling `.expect.json` manifest, if
+// one exists, and resolves each entry against markers into a symbolAttributeTestCase,
+// keyed by the line number of the marker it references. This is what lets assertions
+// live in a file separate from the source being tested - the "cross-file expectation
+// manifest" half of the `//@mark` convention - so indexer fixtures don't need their
+// source mutated with one directive per assertion. Manifests are JSON rather than
+// YAML since that's what the standard library already gives us for the --format=json
+// reporter; nothing else in this repo vendors a YAML decoder.
+func collectManifestTestCases(sourceFilePath string, markers map[string]*marker) (map[int][]*symbolAttributeTestCase, error) {
+	data, err := os.ReadFile(sourceFilePath + ".expect.json")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []manifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s.expect.json: %w", sourceFilePath, err)
+	}
+
+	testCases := map[int][]*symbolAttributeTestCase{}
+	for _, entry := range entries {
+		testCase, ok := markerTestCase(entry.Kind, append([]string{entry.Marker}, entry.Args...), markers)
+		if !ok {
+			return nil, fmt.Errorf("%s.expect.json: marker %q is not declared in %s (expected a //@mark(%s, ...) directive)",
+				sourceFilePath, entry.Marker, filepath.Base(sourceFilePath), entry.Marker)
+		}
+		testCases[testCase.attribute.startLine] = append(testCases[testCase.attribute.startLine], testCase)
+	}
+
+	return testCases, nil
+}
+
+// pendingRangeStart is a `^^^^ start` selector waiting to be paired with the
+// next `^^^ end` selector, tracked on a stack so nested/sequential ranges in
+// the same file resolve to the closest enclosing `start`.
+type pendingRangeStart struct {
+	line int
+	col  int
+}
+
+// collectRangeTestCases pre-scans a file for `^^^^ start` / `^^^ end <kind> <data>`
+// selector pairs and merges each pair into a single symbolAttributeTestCase whose
+// range spans from the `start` selector's line to the `end` selector's line. This
+// is what lets tests assert on occurrences whose Range spans multiple lines, such
+// as a function body's enclosing_range. The result is keyed by the code line the
+// `end` selector follows, the same slot testCasesForLine returns test cases from.
+func collectRangeTestCases(lines []string, commentSyntax string) map[int][]*symbolAttributeTestCase {
+	result := map[int][]*symbolAttributeTestCase{}
+	pending := []*pendingRangeStart{}
+
+	for lineNumber, line := range lines {
+		if lineNumber == 0 || !strings.HasPrefix(strings.TrimSpace(line), commentSyntax) || !strings.Contains(line, "^") {
+			continue
+		}
+
+		col, _, length, rest := caretSelector(line, commentSyntax)
+		fields := strings.SplitN(rest, " ", 2)
+
+		switch fields[0] {
+		case "start":
+			pending = append(pending, &pendingRangeStart{line: lineNumber - 1, col: col})
+
+		case "end":
+			if len(pending) == 0 || len(fields) < 2 {
+				continue
+			}
+			start := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+
+			kindAndData := strings.SplitN(fields[1], " ", 2)
+			kind := kindAndData[0]
+			data := ""
+			if len(kindAndData) > 1 {
+				data = strings.TrimSpace(kindAndData[1])
 			}
+
+			caretCount := length
+			if caretCount == 0 {
+				caretCount = 1
+			}
+			endLine := lineNumber - 1
+
+			result[endLine] = append(result[endLine], &symbolAttributeTestCase{
+				attribute: &symbolAttribute{
+					startLine:      start.line,
+					start:          start.col,
+					endLine:        endLine,
+					endCol:         col + caretCount,
+					kind:           kind,
+					data:           data,
+					additionalData: parseAdditionalData(lines[lineNumber+1:], commentSyntax),
+				},
+				enforceLength: true,
+			})
+		}
+	}
+
+	return result
+}
+
+// attributesForRange returns the symbolAttributes relevant to checking a test
+// case whose selector spans [startLine,startCol]-[endLine,endCol]. A single-line
+// query (startLine == endLine) returns every attribute on that line - the
+// columns are ignored, since isValidTestCaseForAttribute does its own per-attribute
+// column matching - preserving the original per-line behavior. A multi-line query,
+// produced by the `^^^^ start` / `^^^ end` selector pair, instead matches only
+// occurrences whose own range or enclosing_range exactly equals the requested span.
+func attributesForRange(startLine, startCol, endLine, endCol int, document *scip.Document) []*symbolAttribute {
+	if startLine != endLine {
+		return attributesForMultiLineRange(startLine, startCol, endLine, endCol, document)
+	}
+
+	result := []*symbolAttribute{}
+	for _, occ := range document.Occurrences {
+		if occ.Range[0] != int32(startLine) {
+			continue
+		}
+
+		pos, _ := scip.NewRange(occ.Range)
+
+		start := int(pos.Start.Character)
+		length := int(pos.End.Character - pos.Start.Character)
+
+		kind := "reference"
+		switch {
+		case scip.SymbolRole_Definition.Matches(occ):
+			kind = "definition"
+		case scip.SymbolRole_ForwardDefinition.Matches(occ):
+			kind = "forward_definition"
+		case scip.SymbolRole_Import.Matches(occ):
+			kind = "import"
+		}
+		result = append(result, &symbolAttribute{
+			startLine:      startLine,
+			start:          start,
+			length:         length,
+			endLine:        startLine,
+			kind:           kind,
+			data:           occ.Symbol,
+			additionalData: []string{},
+		})
+
+		for _, diagnostic := range occ.Diagnostics {
 			result = append(result, &symbolAttribute{
+				startLine: startLine,
+				start:     start,
+				length:    length,
+				endLine:   startLine,
+				kind:      "diagnostic",
+				data:      diagnostic.Severity.String(),
+				additionalData: []string{
+					diagnostic.Message,
+				},
+			})
+		}
+
+		if len(occ.OverrideDocumentation) > 0 {
+			// OverrideDocumentation replaces the symbol's own documentation for this
+			// specific occurrence, which is exactly what an editor would show on hover.
+			result = append(result, &symbolAttribute{
+				startLine:      startLine,
 				start:          start,
 				length:         length,
-				kind:           kind,
-				data:           occ.Symbol,
-				additionalData: []string{},
+				endLine:        startLine,
+				kind:           "hover",
+				data:           occ.OverrideDocumentation[0],
+				additionalData: occ.OverrideDocumentation[1:],
+			})
+		}
+
+		if enclosingRange := occ.EnclosingRange; len(enclosingRange) > 0 {
+			enclosing, _ := scip.NewRange(enclosingRange)
+			// Only emit the single-line form when the enclosing range genuinely starts
+			// and ends on this line - a function body's enclosing_range is normally
+			// multi-line, and End.Character is a column on an unrelated line in that
+			// case, making length meaningless. attributesForMultiLineRange handles the
+			// multi-line case via the `^^^^ start`/`^^^ end` selector instead.
+			if enclosing.Start.Line == int32(startLine) && enclosing.End.Line == int32(startLine) {
+				result = append(result, &symbolAttribute{
+					startLine:      startLine,
+					start:          int(enclosing.Start.Character),
+					length:         int(enclosing.End.Character - enclosing.Start.Character),
+					endLine:        startLine,
+					kind:           "enclosing_range",
+					data:           occ.Symbol,
+					additionalData: []string{},
+				})
+			}
+		}
+
+		result = append(result, attributesForSymbol(startLine, start, length, occ.Symbol, document.Symbols)...)
+	}
+	return result
+}
+
+// attributesForMultiLineRange handles the startLine != endLine case of
+// attributesForRange: it only matches occurrences (or their enclosing_range)
+// whose span exactly equals [startLine,startCol]-[endLine,endCol], since a
+// multi-line selector is always exact rather than overlap-based. An occurrence
+// whose own Range matches also contributes one "diagnostic" attribute per
+// Diagnostic, the same as the single-line branch, so a `^^^^ start`/`^^^ end
+// diagnostic ...` pair can assert on a diagnostic attached to a multi-line range.
+func attributesForMultiLineRange(startLine, startCol, endLine, endCol int, document *scip.Document) []*symbolAttribute {
+	result := []*symbolAttribute{}
+	for _, occ := range document.Occurrences {
+		if spansExactly(occ.Range, startLine, startCol, endLine, endCol) {
+			result = append(result, &symbolAttribute{
+				startLine: startLine,
+				start:     startCol,
+				endLine:   endLine,
+				endCol:    endCol,
+				kind:      "range",
+				data:      occ.Symbol,
 			})
 
 			for _, diagnostic := range occ.Diagnostics {
 				result = append(result, &symbolAttribute{
-					start:  start,
-					length: length,
-					kind:   "diagnostic",
-					data:   diagnostic.Severity.String(),
+					startLine: startLine,
+					start:     startCol,
+					endLine:   endLine,
+					endCol:    endCol,
+					kind:      "diagnostic",
+					data:      diagnostic.Severity.String(),
 					additionalData: []string{
 						diagnostic.Message,
 					},
 				})
 			}
 		}
+
+		if spansExactly(occ.EnclosingRange, startLine, startCol, endLine, endCol) {
+			result = append(result, &symbolAttribute{
+				startLine: startLine,
+				start:     startCol,
+				endLine:   endLine,
+				endCol:    endCol,
+				kind:      "enclosing_range",
+				data:      occ.Symbol,
+			})
+		}
 	}
 	return result
 }
 
-func parseTestCase(line string, leadingLines []string, commentSyntax string) *symbolAttributeTestCase {
-	start := 0
-	length := 0
-	enforceLength := false
+// spansExactly decodes a SCIP 3- or 4-element range and reports whether it
+// matches [startLine,startCol]-[endLine,endCol] exactly.
+func spansExactly(scipRange []int32, startLine, startCol, endLine, endCol int) bool {
+	if len(scipRange) == 0 {
+		return false
+	}
+	rng, _ := scip.NewRange(scipRange)
+	return int(rng.Start.Line) == startLine && int(rng.Start.Character) == startCol &&
+		int(rng.End.Line) == endLine && int(rng.End.Character) == endCol
+}
 
+// attributesForSymbol lowers the parts of a SymbolInformation that aren't
+// themselves occurrences - relationships, hover documentation, signature -
+// into the same symbolAttribute shape, keyed on the triggering occurrence's
+// position so they can be asserted on like any other attribute on the line.
+func attributesForSymbol(lineNumber, start, length int, symbol string, symbols []*scip.SymbolInformation) []*symbolAttribute {
+	info := symbolInformationFor(symbol, symbols)
+	if info == nil {
+		return nil
+	}
+
+	result := []*symbolAttribute{}
+	for _, rel := range info.Relationships {
+		switch {
+		case rel.IsImplementation:
+			result = append(result, &symbolAttribute{startLine: lineNumber, start: start, length: length, endLine: lineNumber, kind: "implementation", data: rel.Symbol})
+		case rel.IsTypeDefinition:
+			result = append(result, &symbolAttribute{startLine: lineNumber, start: start, length: length, endLine: lineNumber, kind: "type_definition", data: rel.Symbol})
+		case rel.IsDefinition:
+			result = append(result, &symbolAttribute{startLine: lineNumber, start: start, length: length, endLine: lineNumber, kind: "override", data: rel.Symbol})
+		}
+	}
+
+	if len(info.Documentation) > 0 {
+		result = append(result, &symbolAttribute{
+			startLine:      lineNumber,
+			start:          start,
+			length:         length,
+			endLine:        lineNumber,
+			kind:           "documentation",
+			data:           info.Documentation[0],
+			additionalData: info.Documentation[1:],
+		})
+	}
+
+	if info.SignatureDocumentation != nil && info.SignatureDocumentation.Text != "" {
+		signatureLines := strings.Split(info.SignatureDocumentation.Text, "\n")
+		result = append(result, &symbolAttribute{
+			startLine:      lineNumber,
+			start:          start,
+			length:         length,
+			endLine:        lineNumber,
+			kind:           "signature",
+			data:           signatureLines[0],
+			additionalData: signatureLines[1:],
+		})
+	}
+
+	return result
+}
+
+func symbolInformationFor(symbol string, symbols []*scip.SymbolInformation) *scip.SymbolInformation {
+	for _, info := range symbols {
+		if info.Symbol == symbol {
+			return info
+		}
+	}
+	return nil
+}
+
+// caretSelector extracts the column and length/enforceLength information out of
+// a `^`/`<-` selector line, returning the rest of the line (comment syntax,
+// carets, and `<-` stripped) for the caller to interpret. Shared by the
+// single-line grammar (parseTestCase) and the multi-line `start`/`end` grammar
+// (collectRangeTestCases).
+func caretSelector(line string, commentSyntax string) (col int, enforceLength bool, length int, rest string) {
 	if strings.Contains(line, "<-") {
 		// if the test line selects via `<-`, treat the symbol selection
 		// as the location of the commentSyntax
-		start = strings.Index(line, commentSyntax)
+		col = strings.Index(line, commentSyntax)
 		line = strings.Replace(line, "<-", "", 1)
 	} else {
 		// otherwise treat the start as the first `^`
-		start = strings.Index(line, "^")
+		col = strings.Index(line, "^")
 
 		// a single `^` dictates no length enforcement
 		// anything more signifies length should be verified
@@ -225,15 +907,27 @@ func parseTestCase(line string, leadingLines []string, commentSyntax string) *sy
 	}
 
 	// remove the comment prefix & whitespace
-	line = strings.TrimSpace(strings.Replace(line, commentSyntax, "", 1))
-
-	// the type of the symbol should be the first word
-	// this is "definition", "reference", "documentation", "diagnostic", etc..
-	kind := strings.Split(line, " ")[0]
+	rest = strings.TrimSpace(strings.Replace(line, commentSyntax, "", 1))
+	return col, enforceLength, length, rest
+}
 
-	// the data is everything except the type
-	data := strings.TrimSpace(strings.Replace(line, kind, "", 1))
+// isRangeSelectorFragment reports whether line is one half of a `^^^^ start` /
+// `^^^ end <kind> <data>` pair. Such lines are already merged into a single
+// symbolAttributeTestCase by collectRangeTestCases, so testCasesForLine must
+// skip them rather than hand them to parseTestCase.
+func isRangeSelectorFragment(line string, commentSyntax string) bool {
+	if !strings.Contains(line, "^") {
+		return false
+	}
+	_, _, _, rest := caretSelector(line, commentSyntax)
+	fields := strings.SplitN(rest, " ", 2)
+	return fields[0] == "start" || fields[0] == "end"
+}
 
+// parseAdditionalData reads the `>`-prefixed comment lines immediately
+// following a test case's own line, which hold multiline data (documentation
+// bodies, diagnostic messages, etc.).
+func parseAdditionalData(leadingLines []string, commentSyntax string) []string {
 	additionalData := []string{}
 	for i := range leadingLines {
 		leadingLine := leadingLines[i]
@@ -254,12 +948,28 @@ func parseTestCase(line string, leadingLines []string, commentSyntax string) *sy
 		leadingLine = strings.Replace(leadingLine, ">", "", 1)
 		additionalData = append(additionalData, strings.TrimSpace(leadingLine))
 	}
+	return additionalData
+}
+
+func parseTestCase(lineNumber int, line string, leadingLines []string, commentSyntax string) *symbolAttributeTestCase {
+	start, enforceLength, length, rest := caretSelector(line, commentSyntax)
+
+	// the type of the symbol should be the first word
+	// this is "definition", "reference", "documentation", "diagnostic", etc..
+	kind := strings.Split(rest, " ")[0]
+
+	// the data is everything except the type
+	data := strings.TrimSpace(strings.Replace(rest, kind, "", 1))
+
+	additionalData := parseAdditionalData(leadingLines, commentSyntax)
 
 	return &symbolAttributeTestCase{
 		attribute: &symbolAttribute{
+			startLine:      lineNumber,
 			kind:           kind,
 			start:          start,
 			length:         length,
+			endLine:        lineNumber,
 			data:           data,
 			additionalData: additionalData,
 		},
@@ -267,71 +977,387 @@ func parseTestCase(line string, leadingLines []string, commentSyntax string) *sy
 	}
 }
 
-func isValidTestCase(testCase *symbolAttributeTestCase, attributes []*symbolAttribute) bool {
+// isValidTestCase reports whether testCase matches any of attributes. When it
+// doesn't, it also returns the failure category of the closest candidate (the
+// attribute that shares testCase's kind), so callers don't have to reconstruct
+// why a test case failed from the raw attribute list.
+func isValidTestCase(testCase *symbolAttributeTestCase, attributes []*symbolAttribute) (bool, string) {
+	category := "missing_" + testCase.attribute.kind
 	for _, attr := range attributes {
-		if isValidTestCaseForAttribute(testCase, attr) {
-			return true
+		ok, attrCategory := isValidTestCaseForAttribute(testCase, attr)
+		if ok {
+			return true, ""
+		}
+		if attrCategory != "" {
+			category = attrCategory
 		}
 	}
-	return false
+	return false, category
 }
 
-func isValidTestCaseForAttribute(testCase *symbolAttributeTestCase, attr *symbolAttribute) bool {
-	if testCase.enforceLength {
-		if testCase.attribute.length != attr.length || testCase.attribute.start != attr.start {
-			return false
+// symbolDataMatches reports whether attrData satisfies testCaseData, treating a `.`
+// in any space-separated part of testCaseData as a wildcard for the corresponding
+// part of attrData.
+func symbolDataMatches(testCaseData string, attrData string) bool {
+	testCaseParts := strings.Split(testCaseData, " ")
+	attrParts := strings.Split(attrData, " ")
+	for i, testCasePart := range testCaseParts {
+		if testCasePart == "." {
+			continue
 		}
-	} else {
-		if testCase.attribute.start < attr.start || testCase.attribute.start > (attr.start+attr.length)-1 {
+		if i >= len(attrParts) || testCasePart != attrParts[i] {
 			return false
 		}
 	}
+	return true
+}
 
+func isValidTestCaseForAttribute(testCase *symbolAttributeTestCase, attr *symbolAttribute) (bool, string) {
 	if testCase.attribute.kind != attr.kind {
-		return false
+		return false, ""
 	}
 
-	// check if symbols are equal, a `.` character in the testCaseSymbol is considered
-	// a wildcard, and matches the correlating group
-	testCaseSymbolParts := strings.Split(testCase.attribute.data, " ")
-	attrSymbolParts := strings.Split(attr.data, " ")
-	for i, testCaseSymbolPart := range testCaseSymbolParts {
-		if testCaseSymbolPart == "." {
-			continue
+	if testCase.attribute.startLine != testCase.attribute.endLine {
+		// a multi-line `^^^^ start` / `^^^ end` selector always matches the full
+		// range exactly, rather than the single-line column rules below.
+		if testCase.attribute.startLine != attr.startLine || testCase.attribute.start != attr.start ||
+			testCase.attribute.endLine != attr.endLine || testCase.attribute.endCol != attr.endCol {
+			return false, "length_mismatch"
 		}
-		if testCaseSymbolPart != attrSymbolParts[i] {
-			return false
+	} else if testCase.enforceLength {
+		if testCase.attribute.length != attr.length || testCase.attribute.start != attr.start {
+			return false, "length_mismatch"
+		}
+	} else {
+		if testCase.attribute.start < attr.start || testCase.attribute.start > (attr.start+attr.length)-1 {
+			return false, "length_mismatch"
 		}
 	}
 
+	mismatchCategory := "wrong_symbol"
+	if testCase.attribute.kind == "diagnostic" {
+		mismatchCategory = "diagnostic_mismatch"
+	}
+
+	if !symbolDataMatches(testCase.attribute.data, attr.data) {
+		return false, mismatchCategory
+	}
+
 	// only validate additionalData if the testCases provides one
 	// otherwise, ignore what the attribute specifies
 	if len(testCase.attribute.additionalData) > 0 {
 		if !slices.Equal(testCase.attribute.additionalData, attr.additionalData) {
-			return false
+			return false, mismatchCategory
 		}
 	}
 
-	return true
+	return true, ""
 }
 
-func formatFailure(lineNumber int, testCase *symbolAttributeTestCase, attributesAtLine []*symbolAttribute) string {
-	failureDesc := []string{
-		fmt.Sprintf("Failure - row: %d, column: %d", lineNumber, testCase.attribute.start),
-		fmt.Sprintf("  Expected: '%s %s'", testCase.attribute.kind, testCase.attribute.data),
-	}
-	for _, add := range testCase.attribute.additionalData {
-		failureDesc = append(failureDesc, indent(fmt.Sprintf("'%s'", add), 12))
+// testProblem is a single failed assertion, carrying everything a downstream
+// tool (CI annotation, JUnit viewer, SARIF-consuming code review UI) needs
+// without having to re-derive it from the raw attribute list.
+type testProblem struct {
+	Path     string   `json:"path"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	LineText string   `json:"lineText"`
+	Kind     string   `json:"kind"`
+	Expected string   `json:"expected"`
+	Actual   []string `json:"actual"`
+	Category string   `json:"category"`
+}
+
+func newTestProblem(path string, lineNumber int, lines []string, testCase *symbolAttributeTestCase, attributes []*symbolAttribute, category string) testProblem {
+	lineText := ""
+	if lineNumber < len(lines) {
+		lineText = lines[lineNumber]
 	}
 
-	failureDesc = append(failureDesc, "  Actual:")
-	for _, attr := range attributesAtLine {
-		failureDesc = append(failureDesc, fmt.Sprintf("    - '%s %s'", attr.kind, attr.data))
+	actual := []string{}
+	for _, attr := range attributes {
+		entry := fmt.Sprintf("%s %s", attr.kind, attr.data)
 		for _, add := range attr.additionalData {
-			failureDesc = append(failureDesc, indent(fmt.Sprintf("'%s'", add), 6))
+			entry += "\n" + indent(fmt.Sprintf("'%s'", add), 2)
 		}
+		actual = append(actual, entry)
 	}
-	return strings.Join(failureDesc, "\n")
+
+	return testProblem{
+		Path:     path,
+		Line:     lineNumber,
+		Column:   testCase.attribute.start,
+		LineText: lineText,
+		Kind:     testCase.attribute.kind,
+		Expected: testCase.attribute.data,
+		Actual:   actual,
+		Category: category,
+	}
+}
+
+func sortTestProblems(problems []testProblem) {
+	slices.SortFunc(problems, func(a, b testProblem) int {
+		if a.Path != b.Path {
+			return strings.Compare(a.Path, b.Path)
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+}
+
+// --------------------------------- Reporters ---------------------------------
+
+// testReporter receives per-document results as testMain walks the index and
+// decides how (and whether) to render them. Keeping this behind an interface
+// lets --format swap pretty terminal output for a machine-readable one without
+// testMain knowing the difference.
+type testReporter interface {
+	reportDocument(relativePath string, problems []testProblem, successCount int)
+	finish() error
+}
+
+func newTestReporter(format string) (testReporter, error) {
+	switch format {
+	case "", "pretty":
+		return &prettyReporter{}, nil
+	case "json":
+		return &jsonReporter{problems: []testProblem{}}, nil
+	case "junit":
+		return newJunitReporter(), nil
+	case "sarif":
+		return &sarifReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown --format %q: expected one of pretty, json, junit, sarif", format)
+	}
+}
+
+// prettyReporter reproduces the original colored terminal output.
+type prettyReporter struct {
+	hasFailure bool
+}
+
+func (r *prettyReporter) reportDocument(relativePath string, problems []testProblem, successCount int) {
+	if len(problems) > 0 {
+		r.hasFailure = true
+		red := color.New(color.FgRed)
+		red.Printf("✗ %s\n", relativePath)
+
+		for _, problem := range problems {
+			fmt.Println(indent(formatTestProblem(problem), 4))
+		}
+	} else {
+		green := color.New(color.FgGreen)
+		green.Printf("✓ %s (%d assertions)\n", relativePath, successCount)
+	}
+}
+
+func (r *prettyReporter) finish() error {
+	if r.hasFailure {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+func formatTestProblem(problem testProblem) string {
+	desc := []string{
+		fmt.Sprintf("Failure - row: %d, column: %d (%s)", problem.Line, problem.Column, problem.Category),
+		fmt.Sprintf("  Expected: '%s %s'", problem.Kind, problem.Expected),
+		"  Actual:",
+	}
+	for _, actual := range problem.Actual {
+		desc = append(desc, indent(fmt.Sprintf("- '%s'", actual), 4))
+	}
+	return strings.Join(desc, "\n")
+}
+
+// jsonReporter accumulates every problem across all documents and prints them
+// as a single JSON array once the whole index has been walked.
+type jsonReporter struct {
+	problems []testProblem
+}
+
+func (r *jsonReporter) reportDocument(_ string, problems []testProblem, _ int) {
+	r.problems = append(r.problems, problems...)
+}
+
+func (r *jsonReporter) finish() error {
+	sortTestProblems(r.problems)
+
+	data, err := json.MarshalIndent(r.problems, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	if len(r.problems) > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// junitReporter renders results as a single JUnit `<testsuite>` so CI systems
+// can surface a pass/fail result per assertion.
+type junitReporter struct {
+	suite junitTestsuite
+}
+
+type junitTestsuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func newJunitReporter() *junitReporter {
+	return &junitReporter{suite: junitTestsuite{Name: "scip test"}}
+}
+
+func (r *junitReporter) reportDocument(relativePath string, problems []testProblem, successCount int) {
+	r.suite.Tests += successCount + len(problems)
+	r.suite.Failures += len(problems)
+
+	for i := 0; i < successCount; i++ {
+		r.suite.Cases = append(r.suite.Cases, junitTestcase{
+			ClassName: relativePath,
+			Name:      fmt.Sprintf("assertion %d", i+1),
+		})
+	}
+
+	for _, problem := range problems {
+		r.suite.Cases = append(r.suite.Cases, junitTestcase{
+			ClassName: relativePath,
+			Name:      fmt.Sprintf("%s:%d:%d", relativePath, problem.Line, problem.Column),
+			Failure: &junitFailure{
+				Message: fmt.Sprintf("expected %s %s (%s)", problem.Kind, problem.Expected, problem.Category),
+				Text:    strings.Join(problem.Actual, "\n"),
+			},
+		})
+	}
+}
+
+func (r *junitReporter) finish() error {
+	data, err := xml.MarshalIndent(r.suite, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(xml.Header + string(data))
+
+	if r.suite.Failures > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// sarifReporter renders results as a minimal SARIF log so failures can be
+// surfaced as inline annotations in code review UIs.
+type sarifReporter struct {
+	results []sarifResult
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+func (r *sarifReporter) reportDocument(relativePath string, problems []testProblem, _ int) {
+	for _, problem := range problems {
+		r.results = append(r.results, sarifResult{
+			RuleID: problem.Category,
+			Level:  "error",
+			Message: sarifMessage{
+				Text: fmt.Sprintf("expected %s %s, got: %s", problem.Kind, problem.Expected, strings.Join(problem.Actual, "; ")),
+			},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: relativePath},
+					Region: sarifRegion{
+						// SARIF lines/columns are 1-based; scip ranges are 0-based.
+						StartLine:   problem.Line + 1,
+						StartColumn: problem.Column + 1,
+					},
+				},
+			}},
+		})
+	}
+}
+
+func (r *sarifReporter) finish() error {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "scip-test"}},
+			Results: r.results,
+		}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	if len(r.results) > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
 }
 
 // --------------------------------- Utils ---------------------------------
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/scip/bindings/go/scip"
+)
+
+func TestCollectRangeTestCasesPairsStartAndEnd(t *testing.T) {
+	lines := []string{
+		"",
+		"func foo() {",
+		"// ^^^^ start",
+		"}",
+		"// ^^^ end enclosing_range pkg.foo",
+	}
+
+	result := collectRangeTestCases(lines, "//")
+
+	cases, ok := result[3]
+	if !ok || len(cases) != 1 {
+		t.Fatalf("expected exactly one test case keyed on the end selector's code line (3), got %v", result)
+	}
+
+	got := cases[0].attribute
+	want := &symbolAttribute{
+		startLine:      1,
+		start:          3,
+		endLine:        3,
+		endCol:         6,
+		kind:           "enclosing_range",
+		data:           "pkg.foo",
+		additionalData: []string{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("attribute mismatch:\ngot  %+v\nwant %+v", got, want)
+	}
+	if !cases[0].enforceLength {
+		t.Fatal("expected a range selector test case to always enforce its length")
+	}
+}
+
+func TestCollectRangeTestCasesNestedRangesPairToClosestStart(t *testing.T) {
+	lines := []string{
+		"",
+		"func outer() {",
+		"// ^^^^ start",
+		"  func inner() {",
+		"  // ^^^^ start",
+		"  }",
+		"  // ^^^ end enclosing_range pkg.inner",
+		"}",
+		"// ^^^ end enclosing_range pkg.outer",
+	}
+
+	result := collectRangeTestCases(lines, "//")
+
+	inner, ok := result[5]
+	if !ok || len(inner) != 1 || inner[0].attribute.startLine != 3 || inner[0].attribute.data != "pkg.inner" {
+		t.Fatalf("expected the inner range to pair with its own start (line 3), got %v", result[5])
+	}
+
+	outer, ok := result[7]
+	if !ok || len(outer) != 1 || outer[0].attribute.startLine != 1 || outer[0].attribute.data != "pkg.outer" {
+		t.Fatalf("expected the outer range to pair with its own start (line 1), got %v", result[7])
+	}
+}
+
+func TestCollectRangeTestCasesUnmatchedEndIsIgnored(t *testing.T) {
+	lines := []string{
+		"",
+		"}",
+		"// ^^^ end enclosing_range pkg.foo",
+	}
+
+	result := collectRangeTestCases(lines, "//")
+
+	if len(result) != 0 {
+		t.Fatalf("expected an `end` selector with no matching `start` to produce no test cases, got %v", result)
+	}
+}
+
+func TestPreserveUnregeneratedLinesKeepsFragmentsAndContinuations(t *testing.T) {
+	blockLines := []string{
+		"// ^^^^ start",
+		"// ^ definition pkg.foo",
+		"// ^^^ end documentation pkg.foo",
+		"// > line two",
+		"// > line three",
+		"// ^ reference pkg.bar",
+	}
+
+	got := preserveUnregeneratedLines(blockLines, "//")
+	want := []string{
+		"// ^^^^ start",
+		"// ^^^ end documentation pkg.foo",
+		"// > line two",
+		"// > line three",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("preserved lines mismatch:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestPreserveUnregeneratedLinesKeepsSourceOrderWhenInterleaved(t *testing.T) {
+	blockLines := []string{
+		`//@mark(fooDef, "Foo")`,
+		"// ^^^^ start",
+		"// ^^^ end enclosing_range pkg.Foo",
+		`//@definition(fooDef, "scip-go pkg/mod Foo#")`,
+	}
+
+	got := preserveUnregeneratedLines(blockLines, "//")
+	want := []string{
+		`//@mark(fooDef, "Foo")`,
+		"// ^^^^ start",
+		"// ^^^ end enclosing_range pkg.Foo",
+		`//@definition(fooDef, "scip-go pkg/mod Foo#")`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("preserved lines should keep their original relative order:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestWithoutCoveredAttributesDropsOnlyTheCoveredAttribute(t *testing.T) {
+	attributes := []*symbolAttribute{
+		{kind: "definition", start: 4, data: "pkg.foo"},
+		{kind: "reference", start: 4, data: "pkg.foo"},
+		{kind: "implementation", start: 4, data: "pkg.ifaceA"},
+		{kind: "implementation", start: 4, data: "pkg.ifaceB"},
+	}
+	manifestTestCases := []*symbolAttributeTestCase{
+		{attribute: &symbolAttribute{kind: "definition", start: 4, data: "pkg.foo"}},
+		{attribute: &symbolAttribute{kind: "implementation", start: 4, data: "pkg.ifaceA"}},
+	}
+
+	got := withoutCoveredAttributes(attributes, manifestTestCases)
+
+	want := []*symbolAttribute{attributes[1], attributes[3]}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected only uncovered attributes to remain:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestWithoutCoveredAttributesHonorsWildcardData(t *testing.T) {
+	attributes := []*symbolAttribute{
+		{kind: "definition", start: 4, data: "scip-go pkg/mod v1.2.3 Foo#"},
+	}
+	manifestTestCases := []*symbolAttributeTestCase{
+		{attribute: &symbolAttribute{kind: "definition", start: 4, data: "scip-go pkg/mod . Foo#"}},
+	}
+
+	got := withoutCoveredAttributes(attributes, manifestTestCases)
+
+	if len(got) != 0 {
+		t.Fatalf("expected a `.` wildcard segment in the manifest entry to still count as covering the attribute, got %v", got)
+	}
+}
+
+func TestPreserveMarkerDirectiveLinesKeepsMarkAndAssertionDirectives(t *testing.T) {
+	blockLines := []string{
+		`//@mark(fooDef, "Foo")`,
+		`//@definition(fooDef, "scip-go pkg/mod Foo#")`,
+		"// ^ reference pkg.bar",
+	}
+
+	got := preserveUnregeneratedLines(blockLines, "//")
+	want := []string{
+		`//@mark(fooDef, "Foo")`,
+		`//@definition(fooDef, "scip-go pkg/mod Foo#")`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("preserved lines mismatch:\ngot  %v\nwant %v", got, want)
+	}
+}
+
+func TestCollectInlineMarkerTestCasesSkipsMarkAndResolvesAssertions(t *testing.T) {
+	markers := map[string]*marker{
+		"fooDef": {line: 0, start: 5, length: 3},
+	}
+	blockLines := []string{
+		`//@mark(fooDef, "Foo")`,
+		`//@definition(fooDef, "scip-go pkg/mod Foo#")`,
+		"// ^ reference pkg.bar",
+	}
+
+	got := collectInlineMarkerTestCases(blockLines, "//", markers)
+
+	if len(got) != 1 {
+		t.Fatalf("expected exactly one test case from the @definition directive, got %v", got)
+	}
+	if got[0].attribute.kind != "definition" || got[0].attribute.data != "scip-go pkg/mod Foo#" || got[0].attribute.start != 5 {
+		t.Fatalf("unexpected test case: %+v", got[0].attribute)
+	}
+}
+
+func TestCollectManifestTestCasesErrorsOnUnresolvedMarker(t *testing.T) {
+	dir := t.TempDir()
+	sourceFilePath := filepath.Join(dir, "foo.go")
+	manifest := `[{"kind": "definition", "marker": "fooDef", "args": ["scip-go pkg/mod Foo#"]}]`
+	if err := os.WriteFile(sourceFilePath+".expect.json", []byte(manifest), 0o644); err != nil {
+		t.Fatalf("failed to write manifest fixture: %v", err)
+	}
+
+	_, err := collectManifestTestCases(sourceFilePath, map[string]*marker{})
+
+	if err == nil {
+		t.Fatal("expected an error when a manifest entry references a marker with no //@mark declaration, got nil")
+	}
+	if !strings.Contains(err.Error(), "fooDef") {
+		t.Fatalf("expected the error to name the unresolved marker %q, got: %v", "fooDef", err)
+	}
+}
+
+func TestAttributesForMultiLineRangeIncludesDiagnostics(t *testing.T) {
+	document := &scip.Document{
+		Occurrences: []*scip.Occurrence{
+			{
+				Range:  []int32{1, 0, 3, 1},
+				Symbol: "pkg.foo",
+				Diagnostics: []*scip.Diagnostic{
+					{Severity: scip.Severity_Error, Message: "unused variable"},
+				},
+			},
+		},
+	}
+
+	attributes := attributesForMultiLineRange(1, 0, 3, 1, document)
+
+	var diagnostics []*symbolAttribute
+	for _, attr := range attributes {
+		if attr.kind == "diagnostic" {
+			diagnostics = append(diagnostics, attr)
+		}
+	}
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected exactly one diagnostic attribute for a multi-line range, got %v", attributes)
+	}
+	if diagnostics[0].data != scip.Severity_Error.String() || !reflect.DeepEqual(diagnostics[0].additionalData, []string{"unused variable"}) {
+		t.Fatalf("unexpected diagnostic attribute: %+v", diagnostics[0])
+	}
+}
+
+// TestFormatTestCaseLineRoundTrips regenerates a line via formatTestCaseLine and
+// re-parses it with caretSelector, the same way testMain would after a --fix run.
+// A symbol whose column sits before len(commentSyntax) (e.g. column 0 in a
+// go.mod/BUILD file commented with "#") can't be represented as a `^` selector,
+// since the comment prefix itself occupies those columns - formatTestCaseLine
+// falls back to the `<-` selector for that case instead.
+func TestFormatTestCaseLineRoundTrips(t *testing.T) {
+	tests := []struct {
+		name          string
+		attr          *symbolAttribute
+		enforceLength bool
+		commentSyntax string
+	}{
+		{
+			name:          "ordinary column, no length enforcement",
+			attr:          &symbolAttribute{start: 4, length: 3, kind: "definition", data: "pkg.foo"},
+			commentSyntax: "//",
+		},
+		{
+			name:          "ordinary column, enforced length",
+			attr:          &symbolAttribute{start: 4, length: 3, kind: "definition", data: "pkg.foo"},
+			enforceLength: true,
+			commentSyntax: "//",
+		},
+		{
+			name:          "column before the comment prefix",
+			attr:          &symbolAttribute{start: 0, length: 3, kind: "definition", data: "pkg.foo"},
+			commentSyntax: "#",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			line := formatTestCaseLine(tc.attr, tc.enforceLength, tc.commentSyntax)
+
+			col, _, _, _ := caretSelector(line, tc.commentSyntax)
+			if col != tc.attr.start {
+				t.Fatalf("formatTestCaseLine(%q) round-tripped to column %d, want %d (line: %q)", tc.name, col, tc.attr.start, line)
+			}
+		})
+	}
+}